@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultLeaseDuration = 15 * time.Second
+	defaultRenewDeadline = 10 * time.Second
+	defaultRetryPeriod   = 2 * time.Second
+	defaultResourceLock  = resourceLockLeases
+)
+
+const (
+	resourceLockLeases           = "leases"
+	resourceLockEndpointsLeases  = "endpointsleases"
+	resourceLockConfigMapsLeases = "configmapsleases"
+	resourceLockMultiLock        = "multilock"
+)
+
+const (
+	// modeBlocking is the historical behaviour: the process holds the lease
+	// for as long as it is leader and exits once it loses it, relying on
+	// the container runtime to restart the sidecar and re-enter the race.
+	// With several leases, each lease stops after its own first loss and
+	// the process exits once every lease has stopped.
+	modeBlocking = "blocking"
+	// modeGated never exits on lease loss. It keeps running, gates writes
+	// on leadership through the status files and the /leader endpoint, and
+	// re-enters the acquisition loop.
+	modeGated = "gated"
+
+	defaultMode = modeBlocking
+)
+
+// config holds the leader-election knobs that used to be hard-coded in
+// main(), sourced from environment variables so they can be tuned per
+// deployment the same way the upstream Kubernetes controllers are.
+type config struct {
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+	ResourceLock  string
+	Mode          string
+}
+
+// loadConfig reads LEASE_DURATION, RENEW_DEADLINE, RETRY_PERIOD and
+// RESOURCE_LOCK from the environment, falling back to the previous
+// hard-coded defaults, and validates the timing relationship required by
+// client-go's leader election loop.
+func loadConfig() (config, error) {
+	cfg := config{
+		LeaseDuration: defaultLeaseDuration,
+		RenewDeadline: defaultRenewDeadline,
+		RetryPeriod:   defaultRetryPeriod,
+		ResourceLock:  defaultResourceLock,
+		Mode:          defaultMode,
+	}
+
+	var err error
+	if cfg.LeaseDuration, err = durationEnv("LEASE_DURATION", cfg.LeaseDuration); err != nil {
+		return config{}, err
+	}
+	if cfg.RenewDeadline, err = durationEnv("RENEW_DEADLINE", cfg.RenewDeadline); err != nil {
+		return config{}, err
+	}
+	if cfg.RetryPeriod, err = durationEnv("RETRY_PERIOD", cfg.RetryPeriod); err != nil {
+		return config{}, err
+	}
+
+	if lock := os.Getenv("RESOURCE_LOCK"); lock != "" {
+		cfg.ResourceLock = lock
+	}
+	switch cfg.ResourceLock {
+	case resourceLockLeases, resourceLockEndpointsLeases, resourceLockConfigMapsLeases, resourceLockMultiLock:
+	default:
+		return config{}, fmt.Errorf("invalid RESOURCE_LOCK %q: must be one of %s, %s, %s, %s",
+			cfg.ResourceLock, resourceLockLeases, resourceLockEndpointsLeases, resourceLockConfigMapsLeases, resourceLockMultiLock)
+	}
+
+	if mode := os.Getenv("MODE"); mode != "" {
+		cfg.Mode = mode
+	}
+	switch cfg.Mode {
+	case modeBlocking, modeGated:
+	default:
+		return config{}, fmt.Errorf("invalid MODE %q: must be %q or %q", cfg.Mode, modeBlocking, modeGated)
+	}
+
+	if cfg.RenewDeadline >= cfg.LeaseDuration {
+		return config{}, fmt.Errorf("RENEW_DEADLINE (%s) must be less than LEASE_DURATION (%s)", cfg.RenewDeadline, cfg.LeaseDuration)
+	}
+	if cfg.RetryPeriod >= cfg.RenewDeadline {
+		return config{}, fmt.Errorf("RETRY_PERIOD (%s) must be less than RENEW_DEADLINE (%s)", cfg.RetryPeriod, cfg.RenewDeadline)
+	}
+
+	return cfg, nil
+}
+
+// durationEnv returns the parsed duration in name, or def if name is unset.
+// The value may be a Go duration string (e.g. "15s") or a bare integer
+// number of seconds, mirroring how most controller flags accept either.
+func durationEnv(name string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return d, nil
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, nil
+	}
+	return 0, fmt.Errorf("invalid duration for %s: %q", name, v)
+}
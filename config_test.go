@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDurationEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		envVal  string
+		def     time.Duration
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "unset uses default", def: 5 * time.Second, want: 5 * time.Second},
+		{name: "duration string", envVal: "30s", def: time.Second, want: 30 * time.Second},
+		{name: "bare integer seconds", envVal: "45", def: time.Second, want: 45 * time.Second},
+		{name: "invalid value", envVal: "banana", def: time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.envVal != "" {
+				t.Setenv("TEST_DURATION", tt.envVal)
+			}
+			got, err := durationEnv("TEST_DURATION", tt.def)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("durationEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{name: "defaults"},
+		{
+			name: "valid overrides",
+			env: map[string]string{
+				"LEASE_DURATION": "20s",
+				"RENEW_DEADLINE": "15s",
+				"RETRY_PERIOD":   "5s",
+				"RESOURCE_LOCK":  "multilock",
+				"MODE":           "gated",
+			},
+		},
+		{
+			name:    "renew deadline not less than lease duration",
+			env:     map[string]string{"LEASE_DURATION": "15s", "RENEW_DEADLINE": "15s"},
+			wantErr: true,
+		},
+		{
+			name:    "retry period not less than renew deadline",
+			env:     map[string]string{"RENEW_DEADLINE": "10s", "RETRY_PERIOD": "10s"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid resource lock",
+			env:     map[string]string{"RESOURCE_LOCK": "bogus"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid mode",
+			env:     map[string]string{"MODE": "bogus"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			_, err := loadConfig()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
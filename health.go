@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/leaderelection"
+)
+
+// healthzTolerance is added on top of the configured LeaseDuration before the
+// HealthzAdaptor reports the process unhealthy, giving renewal a little
+// slack instead of flapping /healthz the instant a renewal is late.
+const healthzTolerance = 5 * time.Second
+
+// leadershipObserved records, per lease, that OnStartedLeading or
+// OnNewLeader has fired at least once, so /readyz can distinguish "still
+// running the initial leader-election round" from "steady state, just not
+// the leader" for every lease this process runs.
+var leadershipObserved = struct {
+	mu   sync.Mutex
+	seen map[string]bool
+}{seen: map[string]bool{}}
+
+func markLeadershipObserved(lease string) {
+	leadershipObserved.mu.Lock()
+	defer leadershipObserved.mu.Unlock()
+	leadershipObserved.seen[lease] = true
+}
+
+func allLeadershipObserved(leases []string) bool {
+	leadershipObserved.mu.Lock()
+	defer leadershipObserved.mu.Unlock()
+	for _, lease := range leases {
+		if !leadershipObserved.seen[lease] {
+			return false
+		}
+	}
+	return true
+}
+
+// newHealthServer builds (but does not start) the health *http.Server. It
+// serves:
+//   - /healthz: unhealthy if any lease's HealthzAdaptor reports its leader
+//     hasn't renewed within tolerance;
+//   - /readyz: healthy once every lease has completed its first
+//     leader-election round;
+//   - /leader: whether selfID currently holds a given lease, selected with
+//     ?lease=<name> (or the sole configured lease if there is only one).
+//
+// Returning the *http.Server lets the caller shut it down gracefully on
+// SIGTERM instead of dropping connections.
+func newHealthServer(watchdogs map[string]*leaderelection.HealthzAdaptor, selfID string, trackers map[string]*leaderStateTracker, leaseNames []string) *http.Server {
+	port := os.Getenv("HEALTH_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		for lease, watchdog := range watchdogs {
+			if err := watchdog.Check(r); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprintf(w, "lease %s unhealthy: %v", lease, err)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !allLeadershipObserved(leaseNames) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("not ready: leader election has not completed its first round for every lease"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/leader", func(w http.ResponseWriter, r *http.Request) {
+		lease := r.URL.Query().Get("lease")
+		if lease == "" {
+			if len(leaseNames) != 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("?lease=<name> is required when more than one lease is configured"))
+				return
+			}
+			lease = leaseNames[0]
+		}
+
+		tracker, ok := trackers[lease]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, "unknown lease %q", lease)
+			return
+		}
+
+		identity := tracker.get()
+		w.Header().Set("X-Leader-Identity", identity)
+		if identity == selfID && identity != "" {
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, "%s", identity)
+			return
+		}
+		w.WriteHeader(http.StatusLocked)
+		fmt.Fprintf(w, "%s", identity)
+	})
+
+	return &http.Server{
+		Addr:    fmt.Sprintf(":%s", port),
+		Handler: mux,
+	}
+}
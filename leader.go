@@ -0,0 +1,25 @@
+package main
+
+import "sync"
+
+// leaderStateTracker records the identity of the currently known lease
+// holder (including ours) so the /leader endpoint can answer "who is
+// leader right now" without reaching back into the leaderelection
+// callbacks directly. An empty identity means no leader is currently
+// known, e.g. immediately after losing the lease in MODE=gated.
+type leaderStateTracker struct {
+	mu       sync.RWMutex
+	identity string
+}
+
+func (t *leaderStateTracker) set(identity string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.identity = identity
+}
+
+func (t *leaderStateTracker) get() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.identity
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// loadLeaseNames returns the set of leases this process should run
+// independent leader election for. LEASES is a comma-separated list
+// (e.g. "cache-warmer,scheduler,gc"); LEASES_FILE points at a mounted file
+// with one lease name per line, optionally formatted as a YAML list
+// ("- cache-warmer"). If neither is set, LEASE_NAME is used to preserve
+// single-lease behaviour.
+func loadLeaseNames() ([]string, error) {
+	if csv := os.Getenv("LEASES"); csv != "" {
+		names := splitLeaseNames(csv)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("LEASES was set but contained no lease names")
+		}
+		return names, nil
+	}
+
+	if path := os.Getenv("LEASES_FILE"); path != "" {
+		return readLeaseNamesFile(path)
+	}
+
+	leaseName, exists := os.LookupEnv("LEASE_NAME")
+	if !exists {
+		return nil, fmt.Errorf("none of LEASES, LEASES_FILE or LEASE_NAME is set")
+	}
+	return []string{leaseName}, nil
+}
+
+func splitLeaseNames(csv string) []string {
+	var names []string
+	for _, part := range strings.Split(csv, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			names = append(names, part)
+		}
+	}
+	return names
+}
+
+func readLeaseNamesFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading LEASES_FILE %q: %w", path, err)
+	}
+
+	var names []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimSpace(strings.TrimPrefix(line, "-"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("LEASES_FILE %q contained no lease names", path)
+	}
+	return names, nil
+}
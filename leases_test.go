@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitLeaseNames(t *testing.T) {
+	got := splitLeaseNames(" cache-warmer, scheduler ,, gc")
+	want := []string{"cache-warmer", "scheduler", "gc"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitLeaseNames() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadLeaseNames(t *testing.T) {
+	t.Run("LEASES env", func(t *testing.T) {
+		t.Setenv("LEASES", "cache-warmer,scheduler,gc")
+		got, err := loadLeaseNames()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{"cache-warmer", "scheduler", "gc"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("loadLeaseNames() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("LEASES_FILE yaml list", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "leases.yaml")
+		contents := "- cache-warmer\n- scheduler\n# a comment\n\n- gc\n"
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("writing fixture: %v", err)
+		}
+		t.Setenv("LEASES_FILE", path)
+
+		got, err := loadLeaseNames()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{"cache-warmer", "scheduler", "gc"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("loadLeaseNames() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("LEASE_NAME fallback", func(t *testing.T) {
+		t.Setenv("LEASE_NAME", "solo")
+		got, err := loadLeaseNames()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{"solo"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("loadLeaseNames() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("nothing set is an error", func(t *testing.T) {
+		t.Setenv("LEASES", "")
+		t.Setenv("LEASES_FILE", "")
+		if err := os.Unsetenv("LEASE_NAME"); err != nil {
+			t.Fatalf("unsetenv: %v", err)
+		}
+		if _, err := loadLeaseNames(); err == nil {
+			t.Error("expected an error when none of LEASES, LEASES_FILE or LEASE_NAME are set")
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// newResourceLock builds the resourcelock.Interface selected by
+// cfg.ResourceLock. "leases", "endpointsleases" and "configmapsleases" map
+// straight onto client-go's resourcelock.New lock types; "multilock" builds
+// a resourcelock.MultiLock so a rollout can migrate from the legacy
+// Endpoints lock to the Leases lock without a window where old and new
+// clients disagree on who holds the lease.
+func newResourceLock(cfg config, clientset *kubernetes.Clientset, leaseName, namespace, identity string) (resourcelock.Interface, error) {
+	rlc := resourcelock.ResourceLockConfig{
+		Identity: identity,
+	}
+
+	switch cfg.ResourceLock {
+	case resourceLockLeases, resourceLockEndpointsLeases, resourceLockConfigMapsLeases:
+		return resourcelock.New(cfg.ResourceLock, namespace, leaseName, clientset.CoreV1(), clientset.CoordinationV1(), rlc)
+	case resourceLockMultiLock:
+		// The bare "endpoints" and "configmaps" resourcelock types were
+		// removed from client-go (New returns a "lock is removed, migrate
+		// to endpointsleases" error), so the primary here has to be the
+		// still-supported EndpointsLeases lock, which is itself already an
+		// endpoints+leases MultiLock. Pairing it with a pure Leases
+		// secondary gives the same "everyone agrees on the leader during
+		// rollout" property without touching a removed lock type.
+		primary, err := resourcelock.New(resourcelock.EndpointsLeasesResourceLock, namespace, leaseName, clientset.CoreV1(), clientset.CoordinationV1(), rlc)
+		if err != nil {
+			return nil, fmt.Errorf("building multilock primary (endpointsleases): %w", err)
+		}
+		secondary, err := resourcelock.New(resourcelock.LeasesResourceLock, namespace, leaseName, clientset.CoreV1(), clientset.CoordinationV1(), rlc)
+		if err != nil {
+			return nil, fmt.Errorf("building multilock secondary (leases): %w", err)
+		}
+		return &resourcelock.MultiLock{Primary: primary, Secondary: secondary}, nil
+	default:
+		return nil, fmt.Errorf("unsupported RESOURCE_LOCK %q", cfg.ResourceLock)
+	}
+}
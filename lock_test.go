@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+func TestNewResourceLock(t *testing.T) {
+	// kubernetes.NewForConfig only builds REST clients, it doesn't dial the
+	// API server, so a throwaway Host is enough to exercise newResourceLock
+	// without a live cluster.
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: "http://127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("building clientset: %v", err)
+	}
+
+	tests := []struct {
+		resourceLock  string
+		wantMultiLock bool
+	}{
+		{resourceLock: resourceLockLeases},
+		{resourceLock: resourceLockEndpointsLeases},
+		{resourceLock: resourceLockConfigMapsLeases},
+		{resourceLock: resourceLockMultiLock, wantMultiLock: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resourceLock, func(t *testing.T) {
+			lock, err := newResourceLock(config{ResourceLock: tt.resourceLock}, clientset, "my-lease", "my-namespace", "my-id")
+			if err != nil {
+				t.Fatalf("newResourceLock(%q) returned error: %v", tt.resourceLock, err)
+			}
+			if lock == nil {
+				t.Fatalf("newResourceLock(%q) returned a nil lock", tt.resourceLock)
+			}
+			if _, isMultiLock := lock.(*resourcelock.MultiLock); isMultiLock != tt.wantMultiLock {
+				t.Errorf("newResourceLock(%q): got MultiLock=%v, want %v", tt.resourceLock, isMultiLock, tt.wantMultiLock)
+			}
+		})
+	}
+
+	if _, err := newResourceLock(config{ResourceLock: "bogus"}, clientset, "my-lease", "my-namespace", "my-id"); err == nil {
+		t.Error("newResourceLock with an unsupported RESOURCE_LOCK should return an error")
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/client-go/tools/leaderelection"
+)
+
+const defaultMetricsPort = "9090"
+
+var (
+	isLeaderGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "leader_elector_is_leader",
+		Help: "1 if this identity currently holds the given lease, 0 otherwise.",
+	}, []string{"identity", "lease"})
+
+	transitionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "leader_elector_transitions_total",
+		Help: "Number of leadership transitions (acquisitions and losses) observed by this process, per lease.",
+	}, []string{"lease"})
+
+	leaseAcquireSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "leader_elector_lease_acquire_seconds",
+		Help:    "Time spent waiting to acquire a lease, from process start (or the previous loss) to OnStartedLeading.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"lease"})
+
+	lastRenewalTimestamp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "leader_elector_last_renewal_timestamp_seconds",
+		Help: "Unix timestamp of the last leader status-file refresh while this process holds the lease, per lease; set on each refresh tick, not on each API renewal.",
+	}, []string{"lease"})
+
+	podPatchErrorsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "leader_elector_pod_patch_errors_total",
+		Help: "Number of errors encountered patching the pod's role label.",
+	})
+)
+
+// clientGoMetricsProvider adapts client-go's internal leader-election
+// instrumentation onto our registry, so leader_election_master_status and
+// leader_election_slowpath_total show up next to our own leader_elector_*
+// metrics the same way they do in Agones and cluster-autoscaler.
+type clientGoMetricsProvider struct {
+	masterStatus *prometheus.GaugeVec
+	slowpath     *prometheus.CounterVec
+}
+
+func newClientGoMetricsProvider() *clientGoMetricsProvider {
+	return &clientGoMetricsProvider{
+		masterStatus: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "leader_election_master_status",
+			Help: "Gauge of if the reporting system is master of the relevant lease, 0 indicates backup, 1 indicates master.",
+		}, []string{"name"}),
+		slowpath: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "leader_election_slowpath_total",
+			Help: "Total number of slow path exercised in renewing leader leases.",
+		}, []string{"name"}),
+	}
+}
+
+func (p *clientGoMetricsProvider) NewLeaderMetric() leaderelection.LeaderMetric {
+	return &clientGoLeaderMetric{provider: p}
+}
+
+type clientGoLeaderMetric struct {
+	provider *clientGoMetricsProvider
+}
+
+func (m *clientGoLeaderMetric) On(name string) {
+	m.provider.masterStatus.WithLabelValues(name).Set(1)
+}
+
+func (m *clientGoLeaderMetric) Off(name string) {
+	m.provider.masterStatus.WithLabelValues(name).Set(0)
+}
+
+func (m *clientGoLeaderMetric) SlowpathExercised(name string) {
+	m.provider.slowpath.WithLabelValues(name).Inc()
+}
+
+// startMetricsServer serves /metrics on METRICS_PORT (default 9090), kept
+// separate from the health server so scraping can be firewalled off
+// independently of the kubelet probes.
+func startMetricsServer() {
+	port := os.Getenv("METRICS_PORT")
+	if port == "" {
+		port = defaultMetricsPort
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	addr := fmt.Sprintf(":%s", port)
+	fmt.Printf("Starting metrics server on %s\n", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		fmt.Printf("metrics server error: %v\n", err)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// podInfo is the subset of pod identity the sidecar needs: its name (for
+// patching role labels) and namespace (for locating the lease).
+type podInfo struct {
+	Name      string
+	Namespace string
+}
+
+// loadPodInfo resolves the running pod's identity. POD_INFO_PATH, if set,
+// points at a downward-API volume file with "name" and "namespace" entries
+// (one per line, "key=value" or "key: value") and takes precedence,
+// matching the pattern used by Pinniped's downward.PodInfo. Otherwise it
+// falls back to the POD_NAME and NAMESPACE env vars.
+func loadPodInfo() (podInfo, error) {
+	if path := os.Getenv("POD_INFO_PATH"); path != "" {
+		return readPodInfoFile(path)
+	}
+
+	namespace, exists := os.LookupEnv("NAMESPACE")
+	if !exists {
+		return podInfo{}, fmt.Errorf("NAMESPACE not set (and POD_INFO_PATH not set)")
+	}
+	return podInfo{Name: os.Getenv("POD_NAME"), Namespace: namespace}, nil
+}
+
+func readPodInfoFile(path string) (podInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return podInfo{}, fmt.Errorf("reading POD_INFO_PATH %q: %w", path, err)
+	}
+
+	var info podInfo
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			key, value, found = strings.Cut(line, ":")
+		}
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+
+		switch strings.ToLower(key) {
+		case "name":
+			info.Name = value
+		case "namespace":
+			info.Namespace = value
+		}
+	}
+
+	if info.Namespace == "" {
+		return podInfo{}, fmt.Errorf("POD_INFO_PATH %q has no namespace entry", path)
+	}
+	return info, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadPodInfoFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     podInfo
+		wantErr  bool
+	}{
+		{
+			name:     "key=value with quotes",
+			contents: "name=\"my-pod\"\nnamespace=\"my-namespace\"\n",
+			want:     podInfo{Name: "my-pod", Namespace: "my-namespace"},
+		},
+		{
+			name:     "key: value yaml style",
+			contents: "name: my-pod\nnamespace: my-namespace\n",
+			want:     podInfo{Name: "my-pod", Namespace: "my-namespace"},
+		},
+		{
+			name:     "blank lines and comments ignored",
+			contents: "# downward api file\n\nname=my-pod\n\nnamespace=my-namespace\n",
+			want:     podInfo{Name: "my-pod", Namespace: "my-namespace"},
+		},
+		{
+			name:     "missing namespace is an error",
+			contents: "name=my-pod\n",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "podinfo")
+			if err := os.WriteFile(path, []byte(tt.contents), 0644); err != nil {
+				t.Fatalf("writing fixture: %v", err)
+			}
+
+			got, err := readPodInfoFile(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("readPodInfoFile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPodInfo(t *testing.T) {
+	t.Run("POD_INFO_PATH takes precedence", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "podinfo")
+		if err := os.WriteFile(path, []byte("name=my-pod\nnamespace=my-namespace\n"), 0644); err != nil {
+			t.Fatalf("writing fixture: %v", err)
+		}
+		t.Setenv("POD_INFO_PATH", path)
+		t.Setenv("NAMESPACE", "env-namespace")
+		t.Setenv("POD_NAME", "env-pod")
+
+		got, err := loadPodInfo()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := podInfo{Name: "my-pod", Namespace: "my-namespace"}
+		if got != want {
+			t.Errorf("loadPodInfo() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("falls back to env vars", func(t *testing.T) {
+		t.Setenv("POD_INFO_PATH", "")
+		t.Setenv("NAMESPACE", "env-namespace")
+		t.Setenv("POD_NAME", "env-pod")
+
+		got, err := loadPodInfo()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := podInfo{Name: "env-pod", Namespace: "env-namespace"}
+		if got != want {
+			t.Errorf("loadPodInfo() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("neither set is an error", func(t *testing.T) {
+		t.Setenv("POD_INFO_PATH", "")
+		if err := os.Unsetenv("NAMESPACE"); err != nil {
+			t.Fatalf("unsetenv: %v", err)
+		}
+
+		if _, err := loadPodInfo(); err == nil {
+			t.Error("expected an error when neither POD_INFO_PATH nor NAMESPACE is set")
+		}
+	})
+}
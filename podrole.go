@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// patchPodRole sets the per-lease role label role.leader-elector/<lease> on
+// podName to "leader" or "follower", so each concurrently-elected lease
+// gets its own label instead of a single pod-wide "role".
+func patchPodRole(client *kubernetes.Clientset, namespace, podName, lease, role string) error {
+	labelKey := fmt.Sprintf("role.leader-elector/%s", lease)
+	patch := []byte(fmt.Sprintf(`{"metadata":{"labels":{%q:%q}}}`, labelKey, role))
+	_, err := client.CoreV1().Pods(namespace).Patch(
+		context.TODO(),
+		podName,
+		types.StrategicMergePatchType,
+		patch,
+		metav1.PatchOptions{},
+	)
+	if err != nil {
+		fmt.Printf("lease %s: failed to patch pod role: %v\n", lease, err)
+		podPatchErrorsTotal.Inc()
+	}
+	return err
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// leaseRunner owns the on-disk status files and refresh ticker for a single
+// lease under <statusDir>/<lease>/, so each concurrently-elected lease
+// tracks its own "leader"/"follower" role independently.
+type leaseRunner struct {
+	name         string
+	leaderFile   string
+	followerFile string
+
+	mu          sync.Mutex
+	currentRole string
+	roleCancel  context.CancelFunc
+}
+
+func newLeaseRunner(statusDir, name string) (*leaseRunner, error) {
+	dir := filepath.Join(statusDir, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating status directory %q for lease %q: %w", dir, name, err)
+	}
+	return &leaseRunner{
+		name:         name,
+		leaderFile:   filepath.Join(dir, "leader"),
+		followerFile: filepath.Join(dir, "follower"),
+	}, nil
+}
+
+func (r *leaseRunner) setRole(role, identity string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.currentRole == role {
+		return
+	}
+
+	if r.roleCancel != nil {
+		r.roleCancel()
+	}
+
+	os.Remove(r.leaderFile)
+	os.Remove(r.followerFile)
+
+	filePath := r.followerFile
+	if role == "leader" {
+		filePath = r.leaderFile
+	}
+
+	if err := os.WriteFile(filePath, []byte(identity), 0644); err != nil {
+		fmt.Printf("lease %s: failed to write %s file: %v\n", r.name, role, err)
+	}
+	r.currentRole = role
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.roleCancel = cancel
+	go r.refreshLoop(ctx, filePath, identity)
+}
+
+func (r *leaseRunner) refreshLoop(ctx context.Context, filePath, identity string) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			r.mu.Lock()
+			role := r.currentRole
+			r.mu.Unlock()
+
+			// Re-validate and update role file every tick
+			if role == "leader" {
+				if err := os.WriteFile(filePath, []byte(identity), 0644); err != nil {
+					fmt.Printf("lease %s: failed to refresh leader file: %v\n", r.name, err)
+				}
+				lastRenewalTimestamp.WithLabelValues(r.name).Set(float64(now.Unix()))
+			} else if err := os.Chtimes(filePath, now, now); err != nil {
+				fmt.Printf("lease %s: failed to touch %s: %v\n", r.name, filePath, err)
+			}
+		}
+	}
+}
+
+// clearRole removes both status files and stops the refresh ticker without
+// claiming any role, used when shutting down rather than losing the lease
+// to another identity.
+func (r *leaseRunner) clearRole() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.roleCancel != nil {
+		r.roleCancel()
+		r.roleCancel = nil
+	}
+	os.Remove(r.leaderFile)
+	os.Remove(r.followerFile)
+	r.currentRole = ""
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+)
+
+// runLease drives leader election for a single lease until ctx is
+// cancelled (MODE=gated keeps re-entering the acquisition loop after each
+// loss; MODE=blocking returns after the first loss, the way the
+// single-lease sidecar always has).
+func runLease(ctx context.Context, cfg config, clientset *kubernetes.Clientset, identity, namespace, lease string, labelPodRole bool, podName string, watchdog *leaderelection.HealthzAdaptor, tracker *leaderStateTracker, runner *leaseRunner) error {
+	lock, err := newResourceLock(cfg, clientset, lease, namespace, identity)
+	if err != nil {
+		return fmt.Errorf("lease %s: %w", lease, err)
+	}
+
+	acquireStart := time.Now()
+
+	lec := leaderelection.LeaderElectionConfig{
+		Lock: lock,
+		// Name disambiguates client-go's own provider-backed metrics
+		// (leader_election_master_status, leader_election_slowpath_total)
+		// by lease; left unset they all collapse onto one name="" series.
+		Name:            lease,
+		LeaseDuration:   cfg.LeaseDuration,
+		RenewDeadline:   cfg.RenewDeadline,
+		RetryPeriod:     cfg.RetryPeriod,
+		WatchDog:        watchdog,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				markLeadershipObserved(lease)
+				tracker.set(identity)
+				leaseAcquireSeconds.WithLabelValues(lease).Observe(time.Since(acquireStart).Seconds())
+				isLeaderGauge.WithLabelValues(identity, lease).Set(1)
+				transitionsTotal.WithLabelValues(lease).Inc()
+				runner.setRole("leader", identity)
+				if labelPodRole {
+					_ = patchPodRole(clientset, namespace, podName, lease, "leader")
+				}
+				<-ctx.Done()
+			},
+			OnStoppedLeading: func() {
+				// client-go also calls this when a follower's run ends,
+				// which is not a leadership transition.
+				wasLeader := tracker.get() == identity
+				tracker.set("")
+				isLeaderGauge.WithLabelValues(identity, lease).Set(0)
+				if wasLeader {
+					transitionsTotal.WithLabelValues(lease).Inc()
+				}
+
+				if ctx.Err() != nil {
+					// Shutting down: drop the role files and stop the
+					// refresh ticker instead of claiming to be a follower.
+					runner.clearRole()
+					return
+				}
+
+				acquireStart = time.Now()
+				runner.setRole("follower", identity)
+				if labelPodRole {
+					_ = patchPodRole(clientset, namespace, podName, lease, "follower")
+				}
+			},
+			OnNewLeader: func(leaderIdentity string) {
+				markLeadershipObserved(lease)
+				tracker.set(leaderIdentity)
+				if leaderIdentity != identity {
+					runner.setRole("follower", identity)
+					if labelPodRole {
+						_ = patchPodRole(clientset, namespace, podName, lease, "follower")
+					}
+				}
+			},
+		},
+	}
+
+	for {
+		leaderelection.RunOrDie(ctx, lec)
+		if cfg.Mode != modeGated || ctx.Err() != nil {
+			return nil
+		}
+	}
+}